@@ -17,6 +17,7 @@ limitations under the License.
 package config
 
 import (
+	"errors"
 	"fmt"
 	"io"
 
@@ -55,6 +56,12 @@ var (
 
 		# Output as json
 		pi config view --output=json
+
+		# Get the raw certificate data
+		pi config view --raw
+
+		# Flatten the resulting data to a self contained file
+		pi config view --flatten
 		`)
 )
 
@@ -94,11 +101,11 @@ func NewCmdConfigView(out, errOut io.Writer, ConfigAccess clientcmd.ConfigAccess
 	cmdutil.AddPrinterFlags(cmd)
 	cmd.Flags().Set("output", defaultOutputFormat)
 
-	//options.Merge.Default(true)
-	//f := cmd.Flags().VarPF(&options.Merge, "merge", "", "Merge the full hierarchy of pi config files")
-	//f.NoOptDefVal = "true"
-	//cmd.Flags().BoolVar(&options.RawByteData, "raw", false, "Display raw byte data")
-	//cmd.Flags().BoolVar(&options.Flatten, "flatten", false, "Flatten the resulting pi config file into self-contained output (useful for creating portable pi config files)")
+	options.Merge.Default(true)
+	f := cmd.Flags().VarPF(&options.Merge, "merge", "", "Merge the full hierarchy of pi config files")
+	f.NoOptDefVal = "true"
+	cmd.Flags().BoolVar(&options.RawByteData, "raw", false, "Display raw byte data")
+	cmd.Flags().BoolVar(&options.Flatten, "flatten", false, "Flatten the resulting pi config file into self-contained output (useful for creating portable pi config files)")
 	cmd.Flags().BoolVar(&options.Minify, "minify", false, "Remove all information not used by current-context from the output")
 	return cmd
 }
@@ -115,13 +122,13 @@ func (o ViewOptions) Run(out io.Writer, printer printers.ResourcePrinter) error
 		}
 	}
 
-	//if o.Flatten {
-	//	if err := clientcmdapi.FlattenConfig(config); err != nil {
-	//		return err
-	//	}
-	//} else if !o.RawByteData {
-	//	clientcmdapi.ShortenConfig(config)
-	//}
+	if o.Flatten {
+		if err := clientcmdapi.FlattenConfig(config); err != nil {
+			return err
+		}
+	} else if !o.RawByteData {
+		clientcmdapi.ShortenConfig(config)
+	}
 
 	err = printer.PrintObj(config, out)
 	if err != nil {
@@ -132,11 +139,11 @@ func (o ViewOptions) Run(out io.Writer, printer printers.ResourcePrinter) error
 }
 
 func (o *ViewOptions) Complete() bool {
-	//if o.ConfigAccess.IsExplicitFile() {
-	//	if !o.Merge.Provided() {
-	//		o.Merge.Set("false")
-	//	}
-	//}
+	if o.ConfigAccess.IsExplicitFile() {
+		if !o.Merge.Provided() {
+			o.Merge.Set("false")
+		}
+	}
 
 	return true
 }
@@ -152,9 +159,9 @@ func (o ViewOptions) loadConfig() (*clientcmdapi.Config, error) {
 }
 
 func (o ViewOptions) Validate() error {
-	//if !o.Merge.Value() && !o.ConfigAccess.IsExplicitFile() {
-	//	return errors.New("if merge==false a precise file must to specified")
-	//}
+	if !o.Merge.Value() && !o.ConfigAccess.IsExplicitFile() {
+		return errors.New("if merge==false a precise file must to specified")
+	}
 
 	return nil
 }
@@ -162,8 +169,8 @@ func (o ViewOptions) Validate() error {
 // getStartingConfig returns the Config object built from the sources specified by the options, the filename read (only if it was a single file), and an error if something goes wrong
 func (o *ViewOptions) getStartingConfig() (*clientcmdapi.Config, error) {
 	switch {
-	//case !o.Merge.Value():
-	//	return clientcmd.LoadFromFile(o.ConfigAccess.GetExplicitFile())
+	case !o.Merge.Value():
+		return clientcmd.LoadFromFile(o.ConfigAccess.GetExplicitFile())
 
 	default:
 		return o.ConfigAccess.GetStartingConfig()
@@ -0,0 +1,396 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hyperhq/client-go/kubernetes/scheme"
+	"github.com/hyperhq/pi/pkg/pi"
+	"github.com/hyperhq/pi/pkg/pi/cmd/templates"
+	cmdutil "github.com/hyperhq/pi/pkg/pi/cmd/util"
+	"github.com/hyperhq/pi/pkg/pi/util/i18n"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+var (
+	createLong = templates.LongDesc(i18n.T(`
+	Create a resource from a file or from stdin.
+
+	JSON and YAML formats are accepted.`))
+
+	createExample = templates.Examples(i18n.T(`
+	# Create a service using the data in service.json.
+	pi create -f ./service.json
+
+	# Create a resource based on the JSON passed into stdin.
+	cat service.json | pi create -f -
+
+	# Create resources from all manifests found in the dir directory.
+	pi create -f dir/`))
+)
+
+// CreateOptions is the data required to perform the filename-driven "pi create -f" flow.
+type CreateOptions struct {
+	Filenames []string
+	Recursive bool
+	Validate  bool
+}
+
+// NewCmdCreate returns the top-level "create" command, combining the
+// filename-driven manifest path with the typed generator subcommands.
+func NewCmdCreate(f cmdutil.Factory, cmdOut, errOut io.Writer) *cobra.Command {
+	options := &CreateOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "create -f FILENAME",
+		Short:   i18n.T("Create a resource from a file or from stdin."),
+		Long:    createLong,
+		Example: createExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(options.Filenames) == 0 {
+				defaultRunFunc := cmdutil.DefaultSubCommandRun(errOut)
+				defaultRunFunc(cmd, args)
+				return
+			}
+			cmdutil.CheckErr(options.RunCreate(f, cmdOut))
+		},
+	}
+
+	usage := "to use to create the resource"
+	cmdutil.AddFilenameOptionFlags(cmd, &options.Filenames, usage)
+	cmd.Flags().BoolVarP(&options.Recursive, "recursive", "R", false, "Process the directory used in -f, --filename recursively.")
+	cmd.Flags().BoolVar(&options.Validate, "validate", true, "If true, use a schema to validate the input before sending it")
+
+	cmd.AddCommand(NewCmdCreateService(f, cmdOut, errOut))
+
+	return cmd
+}
+
+// RunCreate visits every --filename source, decodes the manifests it finds
+// and dispatches each decoded object to the matching Hyper API call.
+func (o *CreateOptions) RunCreate(f cmdutil.Factory, out io.Writer) error {
+	sources, err := expandSources(o.Filenames, o.Recursive)
+	if err != nil {
+		return err
+	}
+
+	var errs []string
+	for _, source := range sources {
+		data, err := readSource(source)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+
+		docErrs, err := visitDocuments(data, func(doc []byte) error {
+			return createDecodedObject(f, out, source, doc, o.Validate)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", source, err))
+			continue
+		}
+		for _, docErr := range docErrs {
+			errs = append(errs, fmt.Sprintf("%s: %v", source, docErr))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// expandSources turns the raw --filename values into a flat list of
+// individually-readable sources. A directory contributes its .yaml, .yml and
+// .json files; its subdirectories are descended into only when Recursive is
+// set.
+func expandSources(filenames []string, recursive bool) ([]string, error) {
+	var sources []string
+	for _, filename := range filenames {
+		if filename == "-" || isURL(filename) {
+			sources = append(sources, filename)
+			continue
+		}
+
+		info, err := os.Stat(filename)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			sources = append(sources, filename)
+			continue
+		}
+		err = filepath.Walk(filename, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				if path != filename && !recursive {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if ext := filepath.Ext(path); ext == ".yaml" || ext == ".yml" || ext == ".json" {
+				sources = append(sources, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return sources, nil
+}
+
+func isURL(filename string) bool {
+	return strings.HasPrefix(filename, "http://") || strings.HasPrefix(filename, "https://")
+}
+
+// readSource reads the full contents of a single source: stdin ("-"), an
+// http(s) URL, or a path on disk.
+func readSource(source string) ([]byte, error) {
+	switch {
+	case source == "-":
+		return ioutil.ReadAll(os.Stdin)
+	case isURL(source):
+		resp, err := http.Get(source)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unable to read URL %q, server reported %s", source, resp.Status)
+		}
+		return ioutil.ReadAll(resp.Body)
+	default:
+		return ioutil.ReadFile(source)
+	}
+}
+
+// visitDocuments splits data into its individual YAML/JSON documents,
+// invokes fn for each one, and collects any per-document errors.
+func visitDocuments(data []byte, fn func(doc []byte) error) ([]error, error) {
+	var errs []error
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+	for {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			return errs, nil
+		}
+		if err != nil {
+			return errs, err
+		}
+		if len(strings.TrimSpace(string(doc))) == 0 {
+			continue
+		}
+		if err := fn(doc); err != nil {
+			errs = append(errs, err)
+		}
+	}
+}
+
+// createDecodedObject decodes a single manifest document and routes it to
+// the Hyper API call for its kind.
+func createDecodedObject(f cmdutil.Factory, out io.Writer, source string, doc []byte, validate bool) error {
+	if validate {
+		if err := cmdutil.ValidateSchema(doc, f); err != nil {
+			return fmt.Errorf("error validating %q: %v", source, err)
+		}
+	}
+
+	// scheme.Codecs knows the core API types this command can submit.
+	obj, gvk, err := scheme.Codecs.UniversalDeserializer().Decode(doc, nil, nil)
+	if err != nil {
+		return fmt.Errorf("unable to decode %q: %v", source, err)
+	}
+
+	namespace, _, err := f.DefaultNamespace()
+	if err != nil {
+		return err
+	}
+
+	created, err := createObjectByKind(f, namespace, obj)
+	if err != nil {
+		return fmt.Errorf("error creating %s from %q: %v", strings.ToLower(gvk.Kind), source, err)
+	}
+
+	accessor, err := meta.Accessor(created)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "%s/%s created\n", strings.ToLower(gvk.Kind), accessor.GetName())
+	return nil
+}
+
+// createObjectByKind submits a decoded or generated object to the Hyper API
+// call that matches its concrete type, returning the object the server
+// returned.
+func createObjectByKind(f cmdutil.Factory, namespace string, obj runtime.Object) (runtime.Object, error) {
+	clientset, err := f.ClientSet()
+	if err != nil {
+		return nil, err
+	}
+
+	switch o := obj.(type) {
+	case *v1.Pod:
+		return clientset.CoreV1().Pods(namespace).Create(o)
+	case *v1.Service:
+		return clientset.CoreV1().Services(namespace).Create(o)
+	case *v1.Secret:
+		return clientset.CoreV1().Secrets(namespace).Create(o)
+	case *v1.PersistentVolume:
+		return clientset.CoreV1().PersistentVolumes().Create(o)
+	default:
+		return nil, fmt.Errorf("unsupported resource kind %T", obj)
+	}
+}
+
+// CreateSubcommandOptions is the configuration needed to run a
+// generator-based create command such as "pi create service clusterip".
+type CreateSubcommandOptions struct {
+	Name                string
+	StructuredGenerator pi.StructuredGenerator
+	DryRun              bool
+	OutputFormat        string
+	Record              bool
+	SaveConfig          bool
+}
+
+// RunCreateSubcommand generates the object described by options, stamps it
+// with the --record/--save-config annotations when requested, and creates it
+// through the Hyper API unless --dry-run is set. The object is then printed
+// in the --output format when one is given, otherwise a short success
+// message is printed.
+func RunCreateSubcommand(f cmdutil.Factory, cmd *cobra.Command, out io.Writer, options *CreateSubcommandOptions) error {
+	obj, err := options.StructuredGenerator.StructuredGenerate()
+	if err != nil {
+		return err
+	}
+
+	if options.SaveConfig {
+		if err := createApplyAnnotation(obj, scheme.Codecs.LegacyCodec(v1.SchemeGroupVersion)); err != nil {
+			return err
+		}
+	}
+	if options.Record {
+		if err := recordChangeCause(obj, getOriginalCommand()); err != nil {
+			return err
+		}
+	}
+
+	if !options.DryRun {
+		namespace, _, err := f.DefaultNamespace()
+		if err != nil {
+			return err
+		}
+		obj, err = createObjectByKind(f, namespace, obj)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(options.OutputFormat) > 0 {
+		return cmdutil.PrintObject(cmd, obj, out)
+	}
+
+	cmdutil.PrintSuccess(false, out, obj, options.DryRun, "created")
+	return nil
+}
+
+// changeCauseAnnotation is stamped onto an object when --record is set, so
+// that rollout-history style tooling can later show what command produced
+// it.
+const changeCauseAnnotation = "hyper.sh/change-cause"
+
+// recordChangeCause stamps changeCauseAnnotation with changeCause onto obj.
+func recordChangeCause(obj runtime.Object, changeCause string) error {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	annotations := accessor.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[changeCauseAnnotation] = changeCause
+	accessor.SetAnnotations(annotations)
+	return nil
+}
+
+// getOriginalCommand renders os.Args as a single command line, scrubbing
+// flags whose value may be sensitive.
+func getOriginalCommand() string {
+	args := make([]string, len(os.Args))
+	copy(args, os.Args)
+	redactNext := false
+	for i, arg := range args {
+		if redactNext {
+			args[i] = "*****"
+			redactNext = false
+			continue
+		}
+		lower := strings.ToLower(arg)
+		if strings.Contains(lower, "password") || strings.Contains(lower, "token") || strings.Contains(lower, "secret") {
+			if idx := strings.Index(arg, "="); idx >= 0 {
+				args[i] = arg[:idx+1] + "*****"
+			} else {
+				redactNext = true
+			}
+		}
+	}
+	return strings.Join(args, " ")
+}
+
+// lastAppliedConfigAnnotation is stamped onto an object when --save-config
+// is set, mirroring kubectl's own
+// kubectl.kubernetes.io/last-applied-configuration so future diff/apply
+// tooling can compare against it.
+const lastAppliedConfigAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// createApplyAnnotation serializes obj with codec and stamps the result
+// onto obj's own lastAppliedConfigAnnotation.
+func createApplyAnnotation(obj runtime.Object, codec runtime.Encoder) error {
+	original, err := runtime.Encode(codec, obj)
+	if err != nil {
+		return err
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	annotations := accessor.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[lastAppliedConfigAnnotation] = string(original)
+	accessor.SetAnnotations(annotations)
+	return nil
+}
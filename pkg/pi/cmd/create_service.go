@@ -25,7 +25,6 @@ import (
 	"github.com/hyperhq/pi/pkg/pi/cmd/templates"
 	cmdutil "github.com/hyperhq/pi/pkg/pi/cmd/util"
 	"github.com/hyperhq/pi/pkg/pi/util/i18n"
-	"k8s.io/api/core/v1"
 )
 
 // NewCmdCreateService is a macro command to create a new service
@@ -38,9 +37,9 @@ func NewCmdCreateService(f cmdutil.Factory, cmdOut, errOut io.Writer) *cobra.Com
 		Run:     cmdutil.DefaultSubCommandRun(errOut),
 	}
 	cmd.AddCommand(NewCmdCreateServiceClusterIP(f, cmdOut))
-	//cmd.AddCommand(NewCmdCreateServiceNodePort(f, cmdOut))
+	cmd.AddCommand(NewCmdCreateServiceNodePort(f, cmdOut))
 	cmd.AddCommand(NewCmdCreateServiceLoadBalancer(f, cmdOut))
-	//cmd.AddCommand(NewCmdCreateServiceExternalName(f, cmdOut))
+	cmd.AddCommand(NewCmdCreateServiceExternalName(f, cmdOut))
 
 	return cmd
 }
@@ -73,10 +72,12 @@ func NewCmdCreateServiceClusterIP(f cmdutil.Factory, cmdOut io.Writer) *cobra.Co
 			cmdutil.CheckErr(err)
 		},
 	}
-	//cmdutil.AddApplyAnnotationFlags(cmd)
+	cmdutil.AddApplyAnnotationFlags(cmd)
 	//cmdutil.AddValidateFlags(cmd)
-	//cmdutil.AddPrinterFlags(cmd)
-	//cmdutil.AddGeneratorFlags(cmd, cmdutil.ServiceClusterIPGeneratorV1Name)
+	cmdutil.AddPrinterFlags(cmd)
+	cmdutil.AddDryRunFlag(cmd)
+	cmdutil.AddRecordFlag(cmd)
+	cmdutil.AddGeneratorFlags(cmd, pi.ServiceClusterIPGeneratorV1Name)
 	addPortFlags(cmd)
 	cmd.Flags().String("clusterip", "", i18n.T("Assign your own ClusterIP or set to 'None' for a 'headless' service (no loadbalancing)."))
 	return cmd
@@ -86,27 +87,60 @@ func errUnsupportedGenerator(cmd *cobra.Command, generatorName string) error {
 	return cmdutil.UsageErrorf(cmd, "Generator %s not supported. ", generatorName)
 }
 
+// MakeParams builds the parameter map a pi.GeneratorInfo expects, reading
+// each of its declared flag names off cmd. "name" is taken from the
+// already-resolved resource name rather than a flag, since it comes from
+// the command's positional argument. Slice-valued flags (e.g. --tcp,
+// --selector) are read through GetFlagStringSlice rather than
+// Value.String(), which for a StringSlice flag returns pflag's bracketed
+// CSV representation ("[a,b]") instead of the flag's actual values.
+func MakeParams(cmd *cobra.Command, name string, info pi.GeneratorInfo) map[string]interface{} {
+	params := map[string]interface{}{}
+	for _, paramName := range info.ParamNames() {
+		if paramName == "name" {
+			params["name"] = name
+			continue
+		}
+		f := cmd.Flags().Lookup(paramName)
+		if f == nil {
+			continue
+		}
+		if f.Value.Type() == "stringSlice" {
+			params[paramName] = cmdutil.GetFlagStringSlice(cmd, paramName)
+			continue
+		}
+		params[paramName] = f.Value.String()
+	}
+	return params
+}
+
+// generateService looks up generatorName in pi.ServiceGenerators and builds
+// the generator it describes from cmd's flags.
+func generateService(cmd *cobra.Command, name, generatorName string) (pi.StructuredGenerator, error) {
+	info, ok := pi.ServiceGenerators[generatorName]
+	if !ok {
+		return nil, errUnsupportedGenerator(cmd, generatorName)
+	}
+	return pi.ServiceGenerators.Generate(generatorName, MakeParams(cmd, name, info))
+}
+
 // CreateServiceClusterIP is the implementation of the create service clusterip command
 func CreateServiceClusterIP(f cmdutil.Factory, cmdOut io.Writer, cmd *cobra.Command, args []string) error {
 	name, err := NameFromCommandArgs(cmd, args)
 	if err != nil {
 		return err
 	}
-	var generator pi.StructuredGenerator
-	switch generatorName := cmdutil.ServiceClusterIPGeneratorV1Name; generatorName {
-	case cmdutil.ServiceClusterIPGeneratorV1Name:
-		generator = &pi.ServiceCommonGeneratorV1{
-			Name:      name,
-			TCP:       cmdutil.GetFlagStringSlice(cmd, "tcp"),
-			Type:      v1.ServiceTypeClusterIP,
-			ClusterIP: cmdutil.GetFlagString(cmd, "clusterip"),
-		}
-	default:
-		return errUnsupportedGenerator(cmd, generatorName)
+	generator, err := generateService(cmd, name, cmdutil.GetFlagString(cmd, "generator"))
+	if err != nil {
+		return err
 	}
 	return RunCreateSubcommand(f, cmd, cmdOut, &CreateSubcommandOptions{
 		Name:                name,
 		StructuredGenerator: generator,
+		DryRun:              cmdutil.GetDryRunFlag(cmd),
+		OutputFormat:        cmdutil.GetFlagString(cmd, "output"),
+		Record:              cmdutil.GetFlagBool(cmd, "record"),
+		SaveConfig:          cmdutil.GetFlagBool(cmd, "save-config"),
 	})
 }
 
@@ -134,7 +168,9 @@ func NewCmdCreateServiceNodePort(f cmdutil.Factory, cmdOut io.Writer) *cobra.Com
 	cmdutil.AddApplyAnnotationFlags(cmd)
 	cmdutil.AddValidateFlags(cmd)
 	cmdutil.AddPrinterFlags(cmd)
-	cmdutil.AddGeneratorFlags(cmd, cmdutil.ServiceNodePortGeneratorV1Name)
+	cmdutil.AddDryRunFlag(cmd)
+	cmdutil.AddRecordFlag(cmd)
+	cmdutil.AddGeneratorFlags(cmd, pi.ServiceNodePortGeneratorV1Name)
 	cmd.Flags().Int("node-port", 0, "Port used to expose the service on each node in a cluster.")
 	addPortFlags(cmd)
 	return cmd
@@ -146,22 +182,17 @@ func CreateServiceNodePort(f cmdutil.Factory, cmdOut io.Writer, cmd *cobra.Comma
 	if err != nil {
 		return err
 	}
-	var generator pi.StructuredGenerator
-	switch generatorName := cmdutil.GetFlagString(cmd, "generator"); generatorName {
-	case cmdutil.ServiceNodePortGeneratorV1Name:
-		generator = &pi.ServiceCommonGeneratorV1{
-			Name:      name,
-			TCP:       cmdutil.GetFlagStringSlice(cmd, "tcp"),
-			Type:      v1.ServiceTypeNodePort,
-			ClusterIP: "",
-			NodePort:  cmdutil.GetFlagInt(cmd, "node-port"),
-		}
-	default:
-		return errUnsupportedGenerator(cmd, generatorName)
+	generator, err := generateService(cmd, name, cmdutil.GetFlagString(cmd, "generator"))
+	if err != nil {
+		return err
 	}
 	return RunCreateSubcommand(f, cmd, cmdOut, &CreateSubcommandOptions{
 		Name:                name,
 		StructuredGenerator: generator,
+		DryRun:              cmdutil.GetDryRunFlag(cmd),
+		OutputFormat:        cmdutil.GetFlagString(cmd, "output"),
+		Record:              cmdutil.GetFlagBool(cmd, "record"),
+		SaveConfig:          cmdutil.GetFlagBool(cmd, "save-config"),
 	})
 }
 
@@ -186,10 +217,12 @@ func NewCmdCreateServiceLoadBalancer(f cmdutil.Factory, cmdOut io.Writer) *cobra
 			cmdutil.CheckErr(err)
 		},
 	}
-	//cmdutil.AddApplyAnnotationFlags(cmd)
+	cmdutil.AddApplyAnnotationFlags(cmd)
 	//cmdutil.AddValidateFlags(cmd)
-	//cmdutil.AddPrinterFlags(cmd)
-	//cmdutil.AddGeneratorFlags(cmd, cmdutil.ServiceLoadBalancerGeneratorV1Name)
+	cmdutil.AddPrinterFlags(cmd)
+	cmdutil.AddDryRunFlag(cmd)
+	cmdutil.AddRecordFlag(cmd)
+	cmdutil.AddGeneratorFlags(cmd, pi.ServiceLoadBalancerGeneratorV1Name)
 	addPortFlags(cmd)
 	cmd.Flags().StringP("loadbalancerip", "f", "", "Set fip as LoadBalancerIP")
 	cmd.Flags().StringSliceP("selector", "l", []string{}, "Labels selectors for pods")
@@ -202,23 +235,17 @@ func CreateServiceLoadBalancer(f cmdutil.Factory, cmdOut io.Writer, cmd *cobra.C
 	if err != nil {
 		return err
 	}
-	var generator pi.StructuredGenerator
-	switch generatorName := cmdutil.ServiceLoadBalancerGeneratorV1Name; generatorName {
-	case cmdutil.ServiceLoadBalancerGeneratorV1Name:
-		generator = &pi.ServiceCommonGeneratorV1{
-			Name:           name,
-			TCP:            cmdutil.GetFlagStringSlice(cmd, "tcp"),
-			Type:           v1.ServiceTypeLoadBalancer,
-			ClusterIP:      "",
-			LoadBalancerIP: cmdutil.GetFlagString(cmd, "loadbalancerip"),
-			Selector:       cmdutil.GetFlagStringSlice(cmd, "selector"),
-		}
-	default:
-		return errUnsupportedGenerator(cmd, generatorName)
+	generator, err := generateService(cmd, name, cmdutil.GetFlagString(cmd, "generator"))
+	if err != nil {
+		return err
 	}
 	return RunCreateSubcommand(f, cmd, cmdOut, &CreateSubcommandOptions{
 		Name:                name,
 		StructuredGenerator: generator,
+		DryRun:              cmdutil.GetDryRunFlag(cmd),
+		OutputFormat:        cmdutil.GetFlagString(cmd, "output"),
+		Record:              cmdutil.GetFlagBool(cmd, "record"),
+		SaveConfig:          cmdutil.GetFlagBool(cmd, "save-config"),
 	})
 }
 
@@ -231,7 +258,7 @@ var (
 	that exist off platform, on other clusters, or locally.`))
 
 	serviceExternalNameExample = templates.Examples(i18n.T(`
-	# Create a new ExternalName service named my-ns 
+	# Create a new ExternalName service named my-ns
 	pi create service externalname my-ns --external-name bar.com`))
 )
 
@@ -250,7 +277,9 @@ func NewCmdCreateServiceExternalName(f cmdutil.Factory, cmdOut io.Writer) *cobra
 	cmdutil.AddApplyAnnotationFlags(cmd)
 	cmdutil.AddValidateFlags(cmd)
 	cmdutil.AddPrinterFlags(cmd)
-	cmdutil.AddGeneratorFlags(cmd, cmdutil.ServiceExternalNameGeneratorV1Name)
+	cmdutil.AddDryRunFlag(cmd)
+	cmdutil.AddRecordFlag(cmd)
+	cmdutil.AddGeneratorFlags(cmd, pi.ServiceExternalNameGeneratorV1Name)
 	addPortFlags(cmd)
 	cmd.Flags().String("external-name", "", i18n.T("External name of service"))
 	cmd.MarkFlagRequired("external-name")
@@ -263,20 +292,16 @@ func CreateExternalNameService(f cmdutil.Factory, cmdOut io.Writer, cmd *cobra.C
 	if err != nil {
 		return err
 	}
-	var generator pi.StructuredGenerator
-	switch generatorName := cmdutil.GetFlagString(cmd, "generator"); generatorName {
-	case cmdutil.ServiceExternalNameGeneratorV1Name:
-		generator = &pi.ServiceCommonGeneratorV1{
-			Name:         name,
-			Type:         v1.ServiceTypeExternalName,
-			ExternalName: cmdutil.GetFlagString(cmd, "external-name"),
-			ClusterIP:    "",
-		}
-	default:
-		return errUnsupportedGenerator(cmd, generatorName)
+	generator, err := generateService(cmd, name, cmdutil.GetFlagString(cmd, "generator"))
+	if err != nil {
+		return err
 	}
 	return RunCreateSubcommand(f, cmd, cmdOut, &CreateSubcommandOptions{
 		Name:                name,
 		StructuredGenerator: generator,
+		DryRun:              cmdutil.GetDryRunFlag(cmd),
+		OutputFormat:        cmdutil.GetFlagString(cmd, "output"),
+		Record:              cmdutil.GetFlagBool(cmd, "record"),
+		SaveConfig:          cmdutil.GetFlagBool(cmd, "save-config"),
 	})
 }
@@ -0,0 +1,194 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hyperhq/pi/pkg/pi"
+	"github.com/hyperhq/pi/pkg/pi/cmd/templates"
+	cmdutil "github.com/hyperhq/pi/pkg/pi/cmd/util"
+	"github.com/hyperhq/pi/pkg/pi/util/i18n"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	exposeLong = templates.LongDesc(i18n.T(`
+	Take a pod or deployment and expose it as a new Service.
+
+	Looks up the named resource, derives a label selector that matches its
+	pods, and creates a Service that routes traffic to them.`))
+
+	exposeExample = templates.Examples(i18n.T(`
+	# Create a ClusterIP service for pod "redis-master" exposing port 6379 as 6379
+	pi expose pod redis-master --port=6379
+
+	# Create a LoadBalancer service for deployment "web" serving on port 80 and forwarding to port 8080, bound to fip x.x.x.x
+	pi expose deployment web --port=80 --target-port=8080 --type=LoadBalancer --loadbalancerip=x.x.x.x
+
+	# Preview the Service that would be created, without creating it
+	pi expose pod redis-master --port=6379 --dry-run -o yaml`))
+
+	exposeValidArgs = []string{"pod", "deployment"}
+)
+
+// ExposeServiceOptions is the data required to run "pi expose".
+type ExposeServiceOptions struct {
+	ResourceKind   string
+	ResourceName   string
+	Port           int
+	TargetPort     int
+	Type           string
+	Name           string
+	Selector       []string
+	LoadBalancerIP string
+	DryRun         bool
+	OutputFormat   string
+}
+
+// NewCmdExposeService is a command to expose an existing pod or deployment as a Service
+func NewCmdExposeService(f cmdutil.Factory, cmdOut io.Writer) *cobra.Command {
+	options := &ExposeServiceOptions{}
+
+	cmd := &cobra.Command{
+		Use:       "expose (pod | deployment) NAME --port=port [--target-port=number] [--name=name]",
+		Short:     i18n.T("Take a pod or deployment and expose it as a new Service"),
+		Long:      exposeLong,
+		Example:   exposeExample,
+		ValidArgs: exposeValidArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(options.Complete(cmd, args))
+			cmdutil.CheckErr(options.RunExpose(f, cmd, cmdOut))
+		},
+	}
+
+	cmd.Flags().Int("port", 0, "The port that the service should serve on.")
+	cmd.MarkFlagRequired("port")
+	cmd.Flags().Int("target-port", 0, "Port on the pod/deployment that the service should direct traffic to. Defaults to --port.")
+	cmd.Flags().String("type", string(v1.ServiceTypeClusterIP), "Type for this service: ClusterIP or LoadBalancer.")
+	cmd.Flags().String("name", "", "The name for the newly created service. Defaults to the name of the resource being exposed.")
+	cmd.Flags().StringSliceP("selector", "l", []string{}, "A label selector to use for this service. Defaults to the labels of the resource being exposed.")
+	cmd.Flags().StringP("loadbalancerip", "f", "", "Set fip as LoadBalancerIP")
+	cmdutil.AddPrinterFlags(cmd)
+	cmdutil.AddDryRunFlag(cmd)
+	return cmd
+}
+
+// Complete parses the positional arguments and reads the remaining options
+// off cmd's flags.
+func (o *ExposeServiceOptions) Complete(cmd *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return cmdutil.UsageErrorf(cmd, "expose (pod | deployment) NAME --port=port")
+	}
+	o.ResourceKind = args[0]
+	o.ResourceName = args[1]
+
+	o.Port = cmdutil.GetFlagInt(cmd, "port")
+	o.TargetPort = cmdutil.GetFlagInt(cmd, "target-port")
+	o.Type = cmdutil.GetFlagString(cmd, "type")
+	o.Name = cmdutil.GetFlagString(cmd, "name")
+	o.Selector = cmdutil.GetFlagStringSlice(cmd, "selector")
+	o.LoadBalancerIP = cmdutil.GetFlagString(cmd, "loadbalancerip")
+	o.DryRun = cmdutil.GetDryRunFlag(cmd)
+	o.OutputFormat = cmdutil.GetFlagString(cmd, "output")
+
+	if o.TargetPort == 0 {
+		o.TargetPort = o.Port
+	}
+	if o.Name == "" {
+		o.Name = o.ResourceName
+	}
+	return nil
+}
+
+// RunExpose fetches the referenced pod or deployment, derives its label
+// selector unless one was given explicitly, and submits the Service that
+// results.
+func (o *ExposeServiceOptions) RunExpose(f cmdutil.Factory, cmd *cobra.Command, out io.Writer) error {
+	selector := o.Selector
+	if len(selector) == 0 {
+		derived, err := o.selectorForResource(f)
+		if err != nil {
+			return err
+		}
+		selector = derived
+	}
+
+	generator := &pi.ServiceCommonGeneratorV1{
+		Name:     o.Name,
+		TCP:      []string{fmt.Sprintf("%d:%d", o.Port, o.TargetPort)},
+		Type:     v1.ServiceType(o.Type),
+		Selector: selector,
+	}
+	if generator.Type == v1.ServiceTypeLoadBalancer {
+		generator.LoadBalancerIP = o.LoadBalancerIP
+	}
+
+	return RunCreateSubcommand(f, cmd, out, &CreateSubcommandOptions{
+		Name:                o.Name,
+		StructuredGenerator: generator,
+		DryRun:              o.DryRun,
+		OutputFormat:        o.OutputFormat,
+	})
+}
+
+// selectorForResource fetches the named pod or deployment through f and
+// derives a label selector that matches its pods.
+func (o *ExposeServiceOptions) selectorForResource(f cmdutil.Factory) ([]string, error) {
+	namespace, _, err := f.DefaultNamespace()
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := f.ClientSet()
+	if err != nil {
+		return nil, err
+	}
+
+	switch o.ResourceKind {
+	case "pod":
+		pod, err := clientset.CoreV1().Pods(namespace).Get(o.ResourceName, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return labelsToSelector(pod.Labels), nil
+	case "deployment":
+		deployment, err := clientset.AppsV1().Deployments(namespace).Get(o.ResourceName, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		if deployment.Spec.Selector == nil {
+			return nil, fmt.Errorf("deployment %q has no label selector", o.ResourceName)
+		}
+		return labelsToSelector(deployment.Spec.Selector.MatchLabels), nil
+	default:
+		return nil, fmt.Errorf("unsupported resource kind %q, expose only supports \"pod\" and \"deployment\"", o.ResourceKind)
+	}
+}
+
+// labelsToSelector renders a label map as a list with one "key=value" entry
+// per label, for ServiceCommonGeneratorV1's Selector field.
+func labelsToSelector(labels map[string]string) []string {
+	selector := make([]string, 0, len(labels))
+	for k, v := range labels {
+		selector = append(selector, fmt.Sprintf("%s=%s", k, v))
+	}
+	return selector
+}
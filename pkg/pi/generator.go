@@ -0,0 +1,184 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pi
+
+import (
+	"fmt"
+	"strconv"
+
+	"k8s.io/api/core/v1"
+)
+
+// Generator name constants for the service generators below. These are
+// declared here rather than in pkg/pi/cmd/util so that this package never
+// has to import back into pkg/pi/cmd/util, which itself may grow to depend
+// on pkg/pi.
+const (
+	ServiceClusterIPGeneratorV1Name    = "service-clusterip/v1"
+	ServiceNodePortGeneratorV1Name     = "service-nodeport/v1"
+	ServiceLoadBalancerGeneratorV1Name = "service-loadbalancer/v1"
+	ServiceExternalNameGeneratorV1Name = "service-externalname/v1"
+)
+
+// GeneratorParam declares a single named parameter a generator accepts, and
+// whether the caller must supply it.
+type GeneratorParam struct {
+	Name     string
+	Required bool
+}
+
+// GeneratorInfo describes one entry in a GeneratorRegistry: the parameters
+// it accepts, and how to build the generator from their values.
+type GeneratorInfo struct {
+	Params []GeneratorParam
+	New    func(params map[string]interface{}) (StructuredGenerator, error)
+}
+
+// ParamNames returns the name of every parameter the generator accepts, in
+// the order they were declared.
+func (g GeneratorInfo) ParamNames() []string {
+	names := make([]string, 0, len(g.Params))
+	for _, p := range g.Params {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// GeneratorRegistry looks up a GeneratorInfo by generator name, e.g.
+// ServiceClusterIPGeneratorV1Name.
+type GeneratorRegistry map[string]GeneratorInfo
+
+// ValidateParams checks that every required parameter for generatorName is
+// present in params.
+func (r GeneratorRegistry) ValidateParams(generatorName string, params map[string]interface{}) error {
+	info, ok := r[generatorName]
+	if !ok {
+		return fmt.Errorf("generator %q not supported", generatorName)
+	}
+	for _, p := range info.Params {
+		if !p.Required {
+			continue
+		}
+		if value, found := params[p.Name]; !found || value == "" {
+			return fmt.Errorf("Parameter: %s is required", p.Name)
+		}
+	}
+	return nil
+}
+
+// Generate looks up generatorName, validates params against it and builds
+// the StructuredGenerator it describes.
+func (r GeneratorRegistry) Generate(generatorName string, params map[string]interface{}) (StructuredGenerator, error) {
+	info, ok := r[generatorName]
+	if !ok {
+		return nil, fmt.Errorf("generator %q not supported", generatorName)
+	}
+	if err := r.ValidateParams(generatorName, params); err != nil {
+		return nil, err
+	}
+	return info.New(params)
+}
+
+func paramString(params map[string]interface{}, name string) string {
+	value, _ := params[name].(string)
+	return value
+}
+
+// paramStringSlice reads a []string-valued parameter, as built by MakeParams
+// for flags whose Value.Type() is "stringSlice".
+func paramStringSlice(params map[string]interface{}, name string) []string {
+	value, _ := params[name].([]string)
+	return value
+}
+
+func paramInt(params map[string]interface{}, name string) (int, error) {
+	value := paramString(params, name)
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
+
+// ServiceGenerators is the registry of every service generator this package
+// knows how to build, keyed by the *GeneratorV1Name constants declared
+// above.
+var ServiceGenerators = GeneratorRegistry{
+	ServiceClusterIPGeneratorV1Name: {
+		Params: []GeneratorParam{
+			{Name: "name", Required: true},
+			{Name: "tcp", Required: false},
+			{Name: "clusterip", Required: false},
+		},
+		New: func(params map[string]interface{}) (StructuredGenerator, error) {
+			return &ServiceCommonGeneratorV1{
+				Name:      paramString(params, "name"),
+				TCP:       paramStringSlice(params, "tcp"),
+				Type:      v1.ServiceTypeClusterIP,
+				ClusterIP: paramString(params, "clusterip"),
+			}, nil
+		},
+	},
+	ServiceNodePortGeneratorV1Name: {
+		Params: []GeneratorParam{
+			{Name: "name", Required: true},
+			{Name: "tcp", Required: false},
+			{Name: "node-port", Required: false},
+		},
+		New: func(params map[string]interface{}) (StructuredGenerator, error) {
+			nodePort, err := paramInt(params, "node-port")
+			if err != nil {
+				return nil, err
+			}
+			return &ServiceCommonGeneratorV1{
+				Name:     paramString(params, "name"),
+				TCP:      paramStringSlice(params, "tcp"),
+				Type:     v1.ServiceTypeNodePort,
+				NodePort: nodePort,
+			}, nil
+		},
+	},
+	ServiceLoadBalancerGeneratorV1Name: {
+		Params: []GeneratorParam{
+			{Name: "name", Required: true},
+			{Name: "tcp", Required: false},
+			{Name: "loadbalancerip", Required: false},
+			{Name: "selector", Required: false},
+		},
+		New: func(params map[string]interface{}) (StructuredGenerator, error) {
+			return &ServiceCommonGeneratorV1{
+				Name:           paramString(params, "name"),
+				TCP:            paramStringSlice(params, "tcp"),
+				Type:           v1.ServiceTypeLoadBalancer,
+				LoadBalancerIP: paramString(params, "loadbalancerip"),
+				Selector:       paramStringSlice(params, "selector"),
+			}, nil
+		},
+	},
+	ServiceExternalNameGeneratorV1Name: {
+		Params: []GeneratorParam{
+			{Name: "name", Required: true},
+			{Name: "external-name", Required: true},
+		},
+		New: func(params map[string]interface{}) (StructuredGenerator, error) {
+			return &ServiceCommonGeneratorV1{
+				Name:         paramString(params, "name"),
+				Type:         v1.ServiceTypeExternalName,
+				ExternalName: paramString(params, "external-name"),
+			}, nil
+		},
+	},
+}